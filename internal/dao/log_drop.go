@@ -0,0 +1,155 @@
+package dao
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DropRule describes a single line to silence (or, with Keep set, to force
+// through) before it reaches the view buffer. Source selects what the rule
+// matches against: the raw line body ("" or "_line"), or the name of a
+// field a prior pipeline stage extracted.
+type DropRule struct {
+	Source            string
+	Expression        string
+	Value             string
+	OlderThan         time.Duration
+	LongerThan        int
+	DropCounterReason string
+	Keep              bool
+
+	rx *regexp.Regexp
+}
+
+func (r *DropRule) compile() error {
+	if r.Expression == "" {
+		return nil
+	}
+	rx, err := regexp.Compile(r.Expression)
+	if err != nil {
+		return fmt.Errorf("dao: invalid drop rule expression %q: %w", r.Expression, err)
+	}
+	r.rx = rx
+	return nil
+}
+
+func (r *DropRule) matches(ts time.Time, labels map[string]string, line []byte) bool {
+	if r.Expression != "" || r.Value != "" {
+		source := line
+		if r.Source != "" && r.Source != "_line" {
+			v, ok := labels[r.Source]
+			if !ok {
+				return false
+			}
+			source = []byte(v)
+		}
+		switch {
+		case r.rx != nil && !r.rx.Match(source):
+			return false
+		case r.Value != "" && string(source) != r.Value:
+			return false
+		}
+	}
+	if r.OlderThan > 0 && time.Since(ts) < r.OlderThan {
+		return false
+	}
+	if r.LongerThan > 0 && len(line) <= r.LongerThan {
+		return false
+	}
+
+	return true
+}
+
+// reason returns the label this rule's drops are attributed to.
+func (r *DropRule) reason() string {
+	if r.DropCounterReason != "" {
+		return r.DropCounterReason
+	}
+	switch {
+	case r.Expression != "":
+		return "regex"
+	case r.Value != "":
+		return "value"
+	case r.OlderThan > 0:
+		return "older_than"
+	case r.LongerThan > 0:
+		return "longer_than"
+	default:
+		return "rule"
+	}
+}
+
+// DropStage is a pipeline Stage that silences lines matching any of its
+// configured DropRules, and counts what it silenced per reason so the log
+// view can surface a `dropped: regex=123 older_than=4` summary.
+type DropStage struct {
+	rules []DropRule
+
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+// NewDropStage builds a DropStage from the given rules, compiling any regex
+// expressions up front.
+func NewDropStage(rules ...DropRule) (*DropStage, error) {
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &DropStage{rules: rules, counts: make(map[string]int64)}, nil
+}
+
+// Process implements Stage. Rules are evaluated in order; the first one that
+// matches decides the line's fate, so a `keep` rule ahead of a broader drop
+// rule can carve out an exception.
+func (s *DropStage) Process(ts time.Time, labels map[string]string, line []byte) ([]byte, bool) {
+	for i := range s.rules {
+		r := &s.rules[i]
+		if !r.matches(ts, labels, line) {
+			continue
+		}
+		if r.Keep {
+			return line, false
+		}
+		s.record(r.reason())
+		return line, true
+	}
+
+	return line, false
+}
+
+func (s *DropStage) record(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[reason]++
+}
+
+// DroppedSummary renders the per-reason drop counters as shown in the log
+// view header, e.g. "dropped: regex=123 older_than=4". It returns "" once
+// nothing has been dropped yet.
+func (s *DropStage) DroppedSummary() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.counts) == 0 {
+		return ""
+	}
+
+	reasons := make([]string, 0, len(s.counts))
+	for r := range s.counts {
+		reasons = append(reasons, r)
+	}
+	sort.Strings(reasons)
+
+	parts := make([]string, 0, len(reasons))
+	for _, r := range reasons {
+		parts = append(parts, fmt.Sprintf("%s=%d", r, s.counts[r]))
+	}
+
+	return "dropped: " + strings.Join(parts, " ")
+}
@@ -0,0 +1,92 @@
+package dao
+
+import (
+	"testing"
+	"time"
+)
+
+func fixtureDropStream() []*LogItem {
+	now := time.Now()
+	return []*LogItem{
+		{Bytes: []byte(`level=info msg="started"`), Timestamp: now},
+		{Bytes: []byte(`level=debug msg="tick"`), Timestamp: now},
+		{Bytes: []byte(`level=debug msg="tick"`), Timestamp: now},
+		{Bytes: []byte(`level=error msg="boom"`), Timestamp: now},
+		{Bytes: []byte(`level=debug msg="keep me"`), Timestamp: now},
+		{Bytes: []byte(`level=info msg="old"`), Timestamp: now.Add(-time.Hour)},
+	}
+}
+
+func TestDropStageByExtractedField(t *testing.T) {
+	stage, err := NewDropStage(DropRule{Source: "level", Value: "debug"})
+	if err != nil {
+		t.Fatalf("NewDropStage failed: %v", err)
+	}
+	pipeline := NewPipelineWithStages(modifierStage{modifier: NewLogfmtLogModifier()}, stage)
+
+	stream := fixtureDropStream()
+	var kept int
+	for _, item := range stream {
+		item.Render(0, false, pipeline)
+		if !item.Dropped {
+			kept++
+		}
+	}
+
+	if kept != 3 {
+		t.Fatalf("expected 3 surviving lines, got %d", kept)
+	}
+	if got, want := stage.DroppedSummary(), "dropped: value=3"; got != want {
+		t.Fatalf("DroppedSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestDropStageKeepOverridesLaterRule(t *testing.T) {
+	stage, err := NewDropStage(
+		DropRule{Source: "msg", Value: "keep me", Keep: true},
+		DropRule{Source: "level", Value: "debug"},
+	)
+	if err != nil {
+		t.Fatalf("NewDropStage failed: %v", err)
+	}
+	pipeline := NewPipelineWithStages(modifierStage{modifier: NewLogfmtLogModifier()}, stage)
+
+	stream := fixtureDropStream()
+	for _, item := range stream {
+		item.Render(0, false, pipeline)
+	}
+
+	keepMe := stream[4]
+	if keepMe.Dropped {
+		t.Fatalf("expected the 'keep me' line to survive via the Keep rule")
+	}
+	if got, want := stage.DroppedSummary(), "dropped: value=2"; got != want {
+		t.Fatalf("DroppedSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestDropStageOlderThan(t *testing.T) {
+	stage, err := NewDropStage(DropRule{OlderThan: 30 * time.Minute})
+	if err != nil {
+		t.Fatalf("NewDropStage failed: %v", err)
+	}
+	pipeline := NewPipelineWithStages(stage)
+
+	stream := fixtureDropStream()
+	for _, item := range stream {
+		item.Render(0, false, pipeline)
+	}
+
+	if !stream[5].Dropped {
+		t.Fatalf("expected the hour-old line to be dropped")
+	}
+	if got, want := stage.DroppedSummary(), "dropped: older_than=1"; got != want {
+		t.Fatalf("DroppedSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestDropStageInvalidExpression(t *testing.T) {
+	if _, err := NewDropStage(DropRule{Expression: "["}); err == nil {
+		t.Fatalf("expected an error compiling an invalid regex expression")
+	}
+}
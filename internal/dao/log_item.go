@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/derailed/k9s/internal/color"
+	"github.com/derailed/k9s/internal/dao/logql"
 	"github.com/rs/zerolog/log"
 	"github.com/sahilm/fuzzy"
 )
@@ -18,15 +19,34 @@ type LogChan chan *LogItem
 
 var logModifiers = map[string]LogModifier{}
 
+// RegisterLogModifier registers a LogModifier by name. This is the
+// migration path for modifiers that predate the Pipeline/Stage registry
+// (RegisterStage): NewPipeline falls back to logModifiers for any name it
+// doesn't find in stageFactories, so a modifier registered only here --
+// including one wrapped with WrapLegacyModifier -- still runs when named in
+// a pipeline. New built-in stages should use RegisterStage directly.
 func RegisterLogModifier(name string, modifier LogModifier) {
 	logModifiers[name] = modifier
 }
 
 // LogItem represents a container log line.
 type LogItem struct {
-	Pod, Container, Timestamp string
-	SingleContainer           bool
-	Bytes                     []byte
+	Pod, Container  string
+	Timestamp       time.Time
+	SingleContainer bool
+	Bytes           []byte
+	Extracted       map[string]string
+	Dropped         bool
+
+	// processed/processedPipeline/processedBody cache the last pipeline
+	// run over this item's Bytes, so redrawing the same item (e.g.
+	// toggling showTime, or a separate Filter pass after Lines already
+	// rendered it) doesn't re-run stateful stages -- DropStage's counters
+	// and LogfmtLogModifier's column widths -- more than once per
+	// physical line.
+	processed         bool
+	processedPipeline *Pipeline
+	processedBody     []byte
 }
 
 // NewLogItem returns a new item.
@@ -35,17 +55,26 @@ func NewLogItem(b []byte) *LogItem {
 	var l LogItem
 
 	cols := bytes.Split(b[:len(b)-1], space)
-	l.Timestamp = string(cols[0])
+	l.Timestamp = parseLogTimestamp(string(cols[0]))
 	l.Bytes = bytes.Join(cols[1:], space)
 
 	return &l
 }
 
+// parseLogTimestamp parses the RFC3339Nano timestamp k8s prefixes log lines
+// with, falling back to the receipt time when it is missing or malformed.
+func parseLogTimestamp(s string) time.Time {
+	if ts, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return ts
+	}
+	return time.Now()
+}
+
 // NewLogItemFromString returns a new item.
 func NewLogItemFromString(s string) *LogItem {
 	return &LogItem{
 		Bytes:     []byte(s),
-		Timestamp: time.Now().String(),
+		Timestamp: time.Now(),
 	}
 }
 
@@ -85,11 +114,12 @@ var (
 	matcher    = []byte("$1[]")
 )
 
-// Render returns a log line as string.
-func (l *LogItem) Render(paint int, showTime bool, modifier string) []byte {
+// Render returns a log line as bytes, running it through the given
+// pipeline of log processing stages.
+func (l *LogItem) Render(paint int, showTime bool, pipeline *Pipeline) []byte {
 	bb := make([]byte, 0, 200)
 	if showTime {
-		t := l.Timestamp
+		t := l.Timestamp.Format(time.RFC3339Nano)
 		for i := len(t); i < 30; i++ {
 			t += " "
 		}
@@ -106,13 +136,28 @@ func (l *LogItem) Render(paint int, showTime bool, modifier string) []byte {
 		bb = append(bb, ' ')
 	}
 
-	line := append(bb, escPattern.ReplaceAll(l.Bytes, matcher)...)
+	return append(bb, l.process(pipeline)...)
+}
 
-	if logModifier := logModifiers[modifier]; logModifier != nil {
-		return logModifier.Modify(line)
+// process runs the item's raw bytes through pipeline, caching the result so
+// that re-rendering the same item (e.g. a redraw with a different
+// showTime/paint, or a Filter pass after Lines already rendered it) only
+// runs stateful stages once per physical line.
+func (l *LogItem) process(pipeline *Pipeline) []byte {
+	if l.processed && l.processedPipeline == pipeline {
+		return l.processedBody
 	}
 
-	return line
+	escaped := escPattern.ReplaceAll(l.Bytes, matcher)
+	out, extracted, drop := pipeline.Process(l.Timestamp, escaped)
+
+	l.processed = true
+	l.processedPipeline = pipeline
+	l.processedBody = out
+	l.Extracted = extracted
+	l.Dropped = drop
+
+	return out
 }
 
 func colorFor(n string) int {
@@ -134,27 +179,27 @@ func colorFor(n string) int {
 type LogItems []*LogItem
 
 // Lines returns a collection of log lines.
-func (l LogItems) Lines(showTime bool, modifier string) [][]byte {
+func (l LogItems) Lines(showTime bool, pipeline *Pipeline) [][]byte {
 	ll := make([][]byte, len(l))
 	for i, item := range l {
-		ll[i] = item.Render(0, showTime, modifier)
+		ll[i] = item.Render(0, showTime, pipeline)
 	}
 
 	return ll
 }
 
 // StrLines returns a collection of log lines.
-func (l LogItems) StrLines(showTime bool, modifier string) []string {
+func (l LogItems) StrLines(showTime bool, pipeline *Pipeline) []string {
 	ll := make([]string, len(l))
 	for i, item := range l {
-		ll[i] = string(item.Render(0, showTime, modifier))
+		ll[i] = string(item.Render(0, showTime, pipeline))
 	}
 
 	return ll
 }
 
 // Render returns logs as a collection of strings.
-func (l LogItems) Render(showTime bool, modifier string, ll [][]byte) {
+func (l LogItems) Render(showTime bool, pipeline *Pipeline, ll [][]byte) {
 	colors := make(map[string]int, len(l))
 	for i, item := range l {
 		info := item.ID()
@@ -163,7 +208,7 @@ func (l LogItems) Render(showTime bool, modifier string, ll [][]byte) {
 			color = colorFor(info)
 			colors[info] = color
 		}
-		ll[i] = item.Render(color, showTime, modifier)
+		ll[i] = item.Render(color, showTime, pipeline)
 	}
 }
 
@@ -175,16 +220,39 @@ func (l LogItems) DumpDebug(m string) {
 	}
 }
 
+// extractedFieldSelector matches a bare `key=value` filter query, used to
+// select on a field a pipeline stage extracted rather than on raw line text.
+var extractedFieldSelector = regexp.MustCompile(`^(\w+)=(\S+)$`)
+
+// knownExtractedFields are the well-known field names the built-in
+// modifiers surface on LogItem.Extracted (json and klog populate only these;
+// logfmt populates every key of the line). A bare `key=value` query only
+// routes to filterExtracted when key is one of these; anything else (e.g.
+// `status=500`) falls through to filterLogs as a literal substring match,
+// since under the json or klog modifiers, or with no modifier at all, such
+// a key is never extracted and would otherwise silently match nothing.
+var knownExtractedFields = map[string]bool{
+	"level":  true,
+	"msg":    true,
+	"ts":     true,
+	"caller": true,
+	"err":    true,
+}
+
 // Filter filters out log items based on given filter.
-func (l LogItems) Filter(q string, showTime bool, modifier string) ([]int, [][]int, error) {
+func (l LogItems) Filter(q string, showTime bool, pipeline *Pipeline) ([]int, [][]int, error) {
 	if q == "" {
 		return nil, nil, nil
 	}
 	if IsFuzzySelector(q) {
-		mm, ii := l.fuzzyFilter(strings.TrimSpace(q[2:]), showTime, modifier)
+		mm, ii := l.fuzzyFilter(strings.TrimSpace(q[2:]), showTime, pipeline)
 		return mm, ii, nil
 	}
-	matches, indices, err := l.filterLogs(q, showTime, modifier)
+	if mm := extractedFieldSelector.FindStringSubmatch(q); mm != nil && knownExtractedFields[mm[1]] {
+		matches := l.filterExtracted(mm[1], mm[2], showTime, pipeline)
+		return matches, nil, nil
+	}
+	matches, indices, err := l.filterLogs(q, showTime, pipeline)
 	if err != nil {
 		log.Error().Err(err).Msgf("Logs filter failed")
 		return nil, nil, err
@@ -192,11 +260,14 @@ func (l LogItems) Filter(q string, showTime bool, modifier string) ([]int, [][]i
 	return matches, indices, nil
 }
 
-func (l LogItems) fuzzyFilter(q string, showTime bool, modifier string) ([]int, [][]int) {
+func (l LogItems) fuzzyFilter(q string, showTime bool, pipeline *Pipeline) ([]int, [][]int) {
 	q = strings.TrimSpace(q)
 	matches, indices := make([]int, 0, len(l)), make([][]int, 0, 10)
-	mm := fuzzy.Find(q, l.StrLines(showTime, modifier))
+	mm := fuzzy.Find(q, l.StrLines(showTime, pipeline))
 	for _, m := range mm {
+		if l[m.Index].Dropped {
+			continue
+		}
 		matches = append(matches, m.Index)
 		indices = append(indices, m.MatchedIndexes)
 	}
@@ -204,29 +275,69 @@ func (l LogItems) fuzzyFilter(q string, showTime bool, modifier string) ([]int,
 	return matches, indices
 }
 
-func (l LogItems) filterLogs(q string, showTime bool, modifier string) ([]int, [][]int, error) {
-	var invert bool
+// filterExtracted selects items whose pipeline-extracted field matches value
+// exactly, e.g. `level=error`.
+func (l LogItems) filterExtracted(field, value string, showTime bool, pipeline *Pipeline) []int {
+	matches := make([]int, 0, len(l))
+	for i, item := range l {
+		item.Render(0, showTime, pipeline)
+		if item.Dropped {
+			continue
+		}
+		if item.Extracted[field] == value {
+			matches = append(matches, i)
+		}
+	}
+
+	return matches
+}
+
+// logqlOpPattern detects whether a filter query already uses LogQL operator
+// syntax, as opposed to a bare string that needs desugaring.
+var logqlOpPattern = regexp.MustCompile(`\|=|!=|\|~|!~`)
+
+// desugarQuery turns the legacy bare-string and inverse-selector filter forms
+// into a single `|=` / `!=` LogQL op, leaving an already-compound query
+// untouched.
+func desugarQuery(q string) string {
+	if logqlOpPattern.MatchString(q) {
+		return q
+	}
 	if IsInverseSelector(q) {
-		invert = true
-		q = q[1:]
+		return fmt.Sprintf(`!= "%s"`, quoteLogqlValue(q[1:]))
 	}
-	rx, err := regexp.Compile(`(?i)` + q)
+	return fmt.Sprintf(`|= "%s"`, quoteLogqlValue(q))
+}
+
+// quoteLogqlValue escapes a bare value for embedding in a double-quoted
+// LogQL value. It only escapes `\` and `"`, the two bytes logql.tokenize's
+// backslash-unescaping actually undoes, so the pair round-trips exactly --
+// unlike Go's %q, which can emit multi-byte escapes (\t, \xHH, ...) that
+// tokenize would decode back into the wrong bytes.
+func quoteLogqlValue(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
+
+func (l LogItems) filterLogs(q string, showTime bool, pipeline *Pipeline) ([]int, [][]int, error) {
+	query, err := logql.Parse(desugarQuery(q))
 	if err != nil {
 		return nil, nil, err
 	}
+
 	matches, indices := make([]int, 0, len(l)), make([][]int, 0, 10)
-	for i, line := range l.Lines(showTime, modifier) {
-		locs := rx.FindIndex(line)
-		if locs != nil && invert {
+	for i, line := range l.Lines(showTime, pipeline) {
+		if l[i].Dropped {
 			continue
 		}
-		if locs == nil && !invert {
+		ok, ranges := query.Eval(line)
+		if !ok {
 			continue
 		}
 		matches = append(matches, i)
 		ii := make([]int, 0, 10)
-		for i := 0; i < len(locs); i += 2 {
-			for j := locs[i]; j < locs[i+1]; j++ {
+		for _, r := range ranges {
+			for j := r[0]; j < r[1]; j++ {
 				ii = append(ii, j)
 			}
 		}
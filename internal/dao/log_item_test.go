@@ -0,0 +1,44 @@
+package dao
+
+import (
+	"testing"
+	"time"
+)
+
+func fixtureFilterStream() LogItems {
+	now := time.Now()
+	return LogItems{
+		{Bytes: []byte(`level=info msg="request handled" status=200`), Timestamp: now},
+		{Bytes: []byte(`level=error msg="request failed" status=500`), Timestamp: now},
+		{Bytes: []byte(`level=info msg="request handled" status=404`), Timestamp: now},
+	}
+}
+
+func TestFilterBareKeyValueSubstringMatch(t *testing.T) {
+	// status is not one of the well-known extracted fields, so
+	// `status=500` must fall through to a literal substring match against
+	// the rendered line rather than an extracted-field lookup.
+	stream := fixtureFilterStream()
+	pipeline := NewPipeline("logfmt")
+
+	matches, _, err := stream.Filter("status=500", false, pipeline)
+	if err != nil {
+		t.Fatalf("Filter failed: %v", err)
+	}
+	if len(matches) != 1 || matches[0] != 1 {
+		t.Fatalf("Filter(%q) matches = %v, want [1]", "status=500", matches)
+	}
+}
+
+func TestFilterKnownExtractedField(t *testing.T) {
+	stream := fixtureFilterStream()
+	pipeline := NewPipeline("logfmt")
+
+	matches, _, err := stream.Filter("level=error", false, pipeline)
+	if err != nil {
+		t.Fatalf("Filter failed: %v", err)
+	}
+	if len(matches) != 1 || matches[0] != 1 {
+		t.Fatalf("Filter(%q) matches = %v, want [1]", "level=error", matches)
+	}
+}
@@ -1,27 +1,71 @@
 package dao
 
-import zappretty "github.com/maoueh/zap-pretty"
+import (
+	"time"
 
+	zappretty "github.com/maoueh/zap-pretty"
+)
+
+// LogModifier transforms a single log line, optionally parsing a structured
+// format and surfacing the fields it found (level, msg, ts, caller, ...) so
+// later consumers -- filtering, colorization, grouping by trace id -- can
+// use them without re-parsing the line.
 type LogModifier interface {
+	Modify(ts time.Time, line []byte) (out []byte, extracted map[string]string)
+}
+
+// LegacyLogModifier is the pre-structured-log LogModifier contract
+// (Modify(line []byte) []byte). Third-party modifiers built against it keep
+// working when wrapped with WrapLegacyModifier.
+type LegacyLogModifier interface {
 	Modify(line []byte) []byte
 }
 
+// WrapLegacyModifier adapts a LegacyLogModifier to the current LogModifier
+// contract. It surfaces no extracted fields, since the old contract had
+// nowhere to put them.
+func WrapLegacyModifier(m LegacyLogModifier) LogModifier {
+	return legacyModifierAdapter{modifier: m}
+}
+
+type legacyModifierAdapter struct {
+	modifier LegacyLogModifier
+}
+
+func (a legacyModifierAdapter) Modify(_ time.Time, line []byte) ([]byte, map[string]string) {
+	return a.modifier.Modify(line), nil
+}
+
 func init() {
 	RegisterLogModifier("zap-pretty", NewZapPrettyLogModifier())
+	RegisterLogModifier("json", NewJSONLogModifier())
+	RegisterLogModifier("logfmt", NewLogfmtLogModifier())
+	RegisterLogModifier("klog", NewKlogLogModifier())
+
+	// Stages are registered as factories, not shared instances: logfmt's
+	// modifier carries real per-stream state (a sliding column-width
+	// window), so every Pipeline needs its own.
+	RegisterStage("zap-pretty", func() Stage { return modifierStage{modifier: NewZapPrettyLogModifier()} })
+	RegisterStage("json", func() Stage { return modifierStage{modifier: NewJSONLogModifier()} })
+	RegisterStage("logfmt", func() Stage { return modifierStage{modifier: NewLogfmtLogModifier()} })
+	RegisterStage("klog", func() Stage { return modifierStage{modifier: NewKlogLogModifier()} })
 }
 
+// ZapPrettyLogModifier pretty-prints zap's JSON log output.
 type ZapPrettyLogModifier struct {
 }
 
+// NewZapPrettyLogModifier returns a new modifier.
 func NewZapPrettyLogModifier() *ZapPrettyLogModifier {
 	return &ZapPrettyLogModifier{}
 }
 
-func (m *ZapPrettyLogModifier) Modify(line []byte) []byte {
+// Modify implements LogModifier.
+func (m *ZapPrettyLogModifier) Modify(_ time.Time, line []byte) ([]byte, map[string]string) {
 	l := string(line)
 	pretty, err := zappretty.PrettyLine(l, true)
 	if err != nil {
-		return line
+		return line, nil
 	}
-	return []byte(pretty)
+	return []byte(pretty), nil
 }
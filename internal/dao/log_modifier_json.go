@@ -0,0 +1,46 @@
+package dao
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JSONLogModifier parses JSON-structured log lines and surfaces the common
+// fields (level, msg, ts, caller) used by most structured loggers
+// (zerolog, zap, logrus in JSON mode). Lines that fail to parse as a JSON
+// object are returned unchanged.
+type JSONLogModifier struct{}
+
+// NewJSONLogModifier returns a new modifier.
+func NewJSONLogModifier() *JSONLogModifier {
+	return &JSONLogModifier{}
+}
+
+// jsonFieldAliases maps the field name surfaced on LogItem.Extracted to the
+// JSON keys different loggers use for it, tried in order.
+var jsonFieldAliases = map[string][]string{
+	"level":  {"level"},
+	"msg":    {"msg", "message"},
+	"ts":     {"ts", "time"},
+	"caller": {"caller"},
+}
+
+// Modify implements LogModifier.
+func (m *JSONLogModifier) Modify(_ time.Time, line []byte) ([]byte, map[string]string) {
+	var fields map[string]any
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return line, nil
+	}
+
+	extracted := make(map[string]string, len(jsonFieldAliases))
+	for field, aliases := range jsonFieldAliases {
+		for _, alias := range aliases {
+			if s, ok := fields[alias].(string); ok {
+				extracted[field] = s
+				break
+			}
+		}
+	}
+
+	return line, extracted
+}
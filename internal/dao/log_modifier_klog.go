@@ -0,0 +1,42 @@
+package dao
+
+import (
+	"regexp"
+	"time"
+)
+
+// KlogLogModifier parses klog/glog-formatted log lines
+// (`Lmmdd hh:mm:ss.uuuuuu    pid file:line] msg`) and surfaces the common
+// fields (level, caller, msg). Lines that do not match the klog header are
+// returned unchanged.
+type KlogLogModifier struct{}
+
+// NewKlogLogModifier returns a new modifier.
+func NewKlogLogModifier() *KlogLogModifier {
+	return &KlogLogModifier{}
+}
+
+// klogHeader captures the single-letter level, the "file:line]" caller, and
+// the remaining message off a klog/glog line.
+var klogHeader = regexp.MustCompile(`^([IWEF])\d{4} \d{2}:\d{2}:\d{2}\.\d+\s+\d+ ([^:]+:\d+)\] (.*)$`)
+
+var klogLevels = map[byte]string{
+	'I': "info",
+	'W': "warn",
+	'E': "error",
+	'F': "fatal",
+}
+
+// Modify implements LogModifier.
+func (m *KlogLogModifier) Modify(_ time.Time, line []byte) ([]byte, map[string]string) {
+	mm := klogHeader.FindSubmatch(line)
+	if mm == nil {
+		return line, nil
+	}
+
+	return line, map[string]string{
+		"level":  klogLevels[mm[1][0]],
+		"caller": string(mm[2]),
+		"msg":    string(mm[3]),
+	}
+}
@@ -0,0 +1,194 @@
+package dao
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/derailed/k9s/internal/color"
+)
+
+// logfmtColWindow bounds how many recent values are kept per key when
+// sizing that key's column, so a single outlier-long value doesn't pin the
+// column wide forever.
+const logfmtColWindow = 50
+
+// logfmtColors assigns the same well-known keys ZapPrettyLogModifier
+// highlights (level, ts, caller, msg, err) a consistent ANSI color.
+var logfmtColors = map[string]int{
+	"level":  208,
+	"ts":     106,
+	"caller": 245,
+	"msg":    255,
+	"err":    196,
+}
+
+type logfmtPair struct {
+	key, value string
+}
+
+// LogfmtLogModifier parses `key=value key2="quoted value"` lines -- the
+// format emitted by go-kit/log, Loki, Prometheus and many Grafana
+// components -- aligns repeated keys into columns over a sliding window of
+// recent lines, and ANSI-colorizes well-known keys consistently with
+// ZapPrettyLogModifier. Lines that contain no logfmt pairs, or that fail to
+// parse, are returned unchanged.
+type LogfmtLogModifier struct {
+	mu     sync.Mutex
+	widths map[string][]int
+	colW   map[string]int
+}
+
+// NewLogfmtLogModifier returns a new modifier.
+func NewLogfmtLogModifier() *LogfmtLogModifier {
+	return &LogfmtLogModifier{
+		widths: make(map[string][]int),
+		colW:   make(map[string]int),
+	}
+}
+
+// Modify implements LogModifier.
+func (m *LogfmtLogModifier) Modify(_ time.Time, line []byte) ([]byte, map[string]string) {
+	pairs, tail := parseLogfmtPairs(line)
+	if len(pairs) == 0 {
+		return line, nil
+	}
+
+	extracted := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		extracted[p.key] = p.value
+	}
+	widths := m.columnWidths(pairs)
+
+	var out bytes.Buffer
+	for i, p := range pairs {
+		if i > 0 {
+			out.WriteByte(' ')
+		}
+		writePair(&out, p, widths[i])
+	}
+	if tail != "" {
+		out.WriteByte(' ')
+		out.WriteString(tail)
+	}
+
+	return out.Bytes(), extracted
+}
+
+// columnWidths folds this line's value lengths into the sliding window used
+// to size each key's column and returns, for each pair in order, the column
+// width it should be padded to (0 for "msg", the free-text field). It takes
+// the lock once for the whole line rather than once per key.
+func (m *LogfmtLogModifier) columnWidths(pairs []logfmtPair) []int {
+	widths := make([]int, len(pairs))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, p := range pairs {
+		if p.key == "msg" {
+			continue
+		}
+		w := append(m.widths[p.key], len(p.value))
+		if len(w) > logfmtColWindow {
+			w = w[len(w)-logfmtColWindow:]
+		}
+		m.widths[p.key] = w
+
+		max := 0
+		for _, v := range w {
+			if v > max {
+				max = v
+			}
+		}
+		m.colW[p.key] = max
+		widths[i] = max
+	}
+
+	return widths
+}
+
+// writePair colorizes and, for every key but the free-text "msg", pads a
+// key=value pair out to width before writing it to out.
+func writePair(out *bytes.Buffer, p logfmtPair, width int) {
+	c, known := logfmtColors[p.key]
+
+	if known {
+		out.WriteString(color.ANSIColorize(p.key, c))
+	} else {
+		out.WriteString(p.key)
+	}
+	out.WriteByte('=')
+
+	val := p.value
+	if known {
+		val = color.ANSIColorize(val, c)
+	}
+	out.WriteString(val)
+
+	if pad := width - len(p.value); pad > 0 {
+		out.WriteString(strings.Repeat(" ", pad))
+	}
+}
+
+// parseLogfmtPairs tokenizes a logfmt line into its ordered key=value pairs,
+// tolerating bare tokens and quoted values with backslash escapes. Parsing
+// stops as soon as a token isn't of the form key=value; whatever of the
+// line remains at that point is returned verbatim as tail so callers can
+// preserve trailing free text instead of discarding it.
+func parseLogfmtPairs(line []byte) (pairs []logfmtPair, tail string) {
+	s := string(line)
+
+	i := 0
+	for i < len(s) {
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+
+		tokenStart := i
+		for i < len(s) && s[i] != '=' && s[i] != ' ' {
+			i++
+		}
+		if i >= len(s) || s[i] != '=' {
+			return pairs, s[tokenStart:]
+		}
+		key := s[tokenStart:i]
+		i++
+
+		var val string
+		if i < len(s) && s[i] == '"' {
+			i++
+			var b strings.Builder
+			for i < len(s) && s[i] != '"' {
+				if s[i] == '\\' && i+1 < len(s) {
+					b.WriteByte(s[i+1])
+					i += 2
+					continue
+				}
+				b.WriteByte(s[i])
+				i++
+			}
+			if i < len(s) {
+				i++
+			}
+			val = b.String()
+		} else {
+			valStart := i
+			for i < len(s) && s[i] != ' ' {
+				i++
+			}
+			val = s[valStart:i]
+		}
+
+		if key == "" {
+			return pairs, s[tokenStart:]
+		}
+		pairs = append(pairs, logfmtPair{key: key, value: val})
+	}
+
+	return pairs, ""
+}
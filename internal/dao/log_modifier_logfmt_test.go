@@ -0,0 +1,89 @@
+package dao
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogfmtLogModifierColumnAlignment(t *testing.T) {
+	m := NewLogfmtLogModifier()
+
+	out, extracted := m.Modify(time.Now(), []byte(`level=info msg="short"`))
+	if extracted["level"] != "info" || extracted["msg"] != "short" {
+		t.Fatalf("unexpected extracted fields: %+v", extracted)
+	}
+	if len(out) == 0 {
+		t.Fatalf("expected non-empty rendered line")
+	}
+
+	// A longer value for the same key should widen the column used for
+	// subsequent, shorter values of that key.
+	m.Modify(time.Now(), []byte(`level=warning msg="a longer message"`))
+	out2, _ := m.Modify(time.Now(), []byte(`level=info msg="short"`))
+	if len(out2) <= len(out) {
+		t.Fatalf("expected the column-aligned output to grow once a wider value was seen")
+	}
+}
+
+func TestLogfmtLogModifierFallsBackOnUnparseable(t *testing.T) {
+	m := NewLogfmtLogModifier()
+	line := []byte("this is not logfmt at all")
+	out, extracted := m.Modify(time.Now(), line)
+	if string(out) != string(line) {
+		t.Fatalf("expected unparseable line to pass through unchanged, got %q", out)
+	}
+	if extracted != nil {
+		t.Fatalf("expected no extracted fields, got %+v", extracted)
+	}
+}
+
+func TestLogfmtLogModifierPreservesTrailingFreeText(t *testing.T) {
+	m := NewLogfmtLogModifier()
+	out, extracted := m.Modify(time.Now(), []byte(`level=info msg="hello" extra free text here`))
+	if extracted["level"] != "info" || extracted["msg"] != "hello" {
+		t.Fatalf("unexpected extracted fields: %+v", extracted)
+	}
+	if !strings.Contains(string(out), "extra free text here") {
+		t.Fatalf("expected trailing free text to survive in the rendered output, got %q", out)
+	}
+}
+
+func TestLogfmtLogModifierPerformanceBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping performance budget check in short mode")
+	}
+
+	m := NewLogfmtLogModifier()
+	line := []byte(`level=info ts=2026-07-26T00:00:00Z caller=main.go:42 msg="request handled" status=200`)
+
+	const iterations = 10000
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		m.Modify(time.Now(), line)
+	}
+	perOp := time.Since(start) / iterations
+
+	// The budget is generous relative to BenchmarkLogfmtLogModifier's
+	// steady-state cost so this only catches gross regressions rather than
+	// machine noise.
+	const budget = 10 * time.Microsecond
+	if perOp > budget {
+		t.Fatalf("Modify took %s/op, want under %s", perOp, budget)
+	}
+}
+
+func BenchmarkLogfmtLogModifier(b *testing.B) {
+	m := NewLogfmtLogModifier()
+	lines := make([][]byte, 100000)
+	for i := range lines {
+		lines[i] = []byte(fmt.Sprintf(
+			`level=info ts=2026-07-26T00:00:00Z caller=main.go:%d msg="request handled" status=200`, i%1000))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Modify(time.Now(), lines[i%len(lines)])
+	}
+}
@@ -0,0 +1,92 @@
+package dao
+
+import "time"
+
+// Stage processes a single log line, optionally enriching the shared labels
+// map with fields it extracted and optionally flagging the line for removal.
+// Stages are composed into a Pipeline and run in the order they were added,
+// each one seeing the output of the one before it.
+type Stage interface {
+	Process(ts time.Time, labels map[string]string, line []byte) (newLine []byte, drop bool)
+}
+
+var stageFactories = map[string]func() Stage{}
+
+// RegisterStage registers a named pipeline stage under a factory rather
+// than a shared instance: some stages (e.g. the logfmt modifier) carry real
+// per-stream state such as a sliding column-width window, so every Pipeline
+// built from a name needs its own instance instead of sharing one across
+// every configured resource/container.
+func RegisterStage(name string, factory func() Stage) {
+	stageFactories[name] = factory
+}
+
+// Pipeline is an ordered sequence of log processing stages, modeled on
+// Promtail's stage pipeline. A Pipeline is built once per configured
+// resource/container and reused across all the lines it renders.
+type Pipeline struct {
+	stages []Stage
+}
+
+// NewPipeline builds a Pipeline out of the named stages, in order. A name is
+// first looked up in stageFactories; if it isn't registered there, it falls
+// back to logModifiers, so a LogModifier registered only via
+// RegisterLogModifier (e.g. a third-party modifier built against the old
+// Modify(line []byte) []byte signature and wrapped with WrapLegacyModifier)
+// still runs. Unknown stage names are silently skipped so a typo in
+// views.yml degrades to a no-op stage rather than breaking log rendering.
+func NewPipeline(names ...string) *Pipeline {
+	p := Pipeline{stages: make([]Stage, 0, len(names))}
+	for _, n := range names {
+		if f, ok := stageFactories[n]; ok {
+			p.stages = append(p.stages, f())
+			continue
+		}
+		if m, ok := logModifiers[n]; ok {
+			p.stages = append(p.stages, modifierStage{modifier: m})
+		}
+	}
+
+	return &p
+}
+
+// NewPipelineWithStages builds a Pipeline out of already-constructed stages.
+// Use this instead of NewPipeline for stages that need per-resource
+// configuration (e.g. a DropStage's rules) rather than a bare registry name.
+func NewPipelineWithStages(stages ...Stage) *Pipeline {
+	return &Pipeline{stages: stages}
+}
+
+// Process runs line through every stage in order, threading the extracted
+// labels map between them. It stops early and reports drop=true as soon as
+// any stage asks for the line to be dropped.
+func (p *Pipeline) Process(ts time.Time, line []byte) (out []byte, extracted map[string]string, drop bool) {
+	out, extracted = line, map[string]string{}
+	if p == nil {
+		return out, extracted, false
+	}
+
+	for _, s := range p.stages {
+		var d bool
+		out, d = s.Process(ts, extracted, out)
+		if d {
+			return out, extracted, true
+		}
+	}
+
+	return out, extracted, false
+}
+
+// modifierStage adapts a LogModifier to run as a single pipeline stage,
+// merging whatever fields it extracts into the pipeline's shared labels.
+type modifierStage struct {
+	modifier LogModifier
+}
+
+func (s modifierStage) Process(ts time.Time, labels map[string]string, line []byte) ([]byte, bool) {
+	out, extracted := s.modifier.Modify(ts, line)
+	for k, v := range extracted {
+		labels[k] = v
+	}
+	return out, false
+}
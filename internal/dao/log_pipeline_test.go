@@ -0,0 +1,32 @@
+package dao
+
+import (
+	"testing"
+	"time"
+)
+
+type upperCaseModifier struct{}
+
+func (upperCaseModifier) Modify(line []byte) []byte {
+	out := make([]byte, len(line))
+	for i, b := range line {
+		if b >= 'a' && b <= 'z' {
+			b -= 'a' - 'A'
+		}
+		out[i] = b
+	}
+	return out
+}
+
+func TestNewPipelineFallsBackToLogModifiers(t *testing.T) {
+	RegisterLogModifier("upper-case-test", WrapLegacyModifier(upperCaseModifier{}))
+
+	pipeline := NewPipeline("upper-case-test")
+	out, _, drop := pipeline.Process(time.Now(), []byte("hello"))
+	if drop {
+		t.Fatalf("expected the line to survive")
+	}
+	if string(out) != "HELLO" {
+		t.Fatalf("out = %q, want %q", out, "HELLO")
+	}
+}
@@ -0,0 +1,176 @@
+// Package logql implements a small LogQL-inspired grammar for chaining line
+// filter operators, as used by Loki/Promtail: a sequence of `|=`, `!=`,
+// `|~` and `!~` operators separated by whitespace, each followed by a
+// quoted or bare value, e.g.:
+//
+//	|~ "GET /api" != "health" |~ "5\d\d"
+package logql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// OpKind identifies a LogQL-style line filter operator.
+type OpKind int
+
+const (
+	// OpContains keeps lines containing Value verbatim (`|=`).
+	OpContains OpKind = iota
+	// OpNotContains drops lines containing Value verbatim (`!=`).
+	OpNotContains
+	// OpMatch keeps lines matching the Value regex (`|~`).
+	OpMatch
+	// OpNotMatch drops lines matching the Value regex (`!~`).
+	OpNotMatch
+)
+
+// Op is a single filter operation in a Query.
+type Op struct {
+	Kind  OpKind
+	Value string
+	rx    *regexp.Regexp
+}
+
+// Query is an ordered sequence of filter Ops, evaluated left to right with
+// short-circuiting on the first op that rejects a line.
+type Query struct {
+	Ops []Op
+}
+
+var opTokens = map[string]OpKind{
+	"|=": OpContains,
+	"!=": OpNotContains,
+	"|~": OpMatch,
+	"!~": OpNotMatch,
+}
+
+// Parse parses a raw LogQL line filter expression into a Query and compiles
+// any regex operands. raw must be a sequence of operator/value pairs; a
+// value is either a bare token or a double-quoted string with `\`-escapes.
+func Parse(raw string) (*Query, error) {
+	toks, err := tokenize(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks)%2 != 0 {
+		return nil, fmt.Errorf("logql: dangling operator or value in %q", raw)
+	}
+
+	q := Query{Ops: make([]Op, 0, len(toks)/2)}
+	for i := 0; i < len(toks); i += 2 {
+		kind, ok := opTokens[toks[i]]
+		if !ok {
+			return nil, fmt.Errorf("logql: expected operator, got %q", toks[i])
+		}
+		if _, isOp := opTokens[toks[i+1]]; isOp {
+			return nil, fmt.Errorf("logql: expected value, got operator %q", toks[i+1])
+		}
+		op := Op{Kind: kind, Value: toks[i+1]}
+
+		// All ops match case-insensitively, same as the pre-LogQL bare
+		// filter did. `|=`/`!=` compile their literal Value via QuoteMeta
+		// so they still match verbatim (just case-folded), while
+		// `|~`/`!~` compile Value as a regex.
+		pattern := op.Value
+		if kind == OpContains || kind == OpNotContains {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		rx, err := regexp.Compile(`(?i)` + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("logql: invalid regex %q: %w", op.Value, err)
+		}
+		op.rx = rx
+
+		q.Ops = append(q.Ops, op)
+	}
+
+	return &q, nil
+}
+
+// tokenize splits raw into alternating operator/value tokens, honoring
+// double-quoted values with backslash escapes.
+func tokenize(raw string) ([]string, error) {
+	toks := make([]string, 0, 8)
+	s := raw
+	for i := 0; i < len(s); {
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+
+		if i+1 < len(s) {
+			if _, ok := opTokens[s[i:i+2]]; ok {
+				toks = append(toks, s[i:i+2])
+				i += 2
+				continue
+			}
+		}
+
+		if s[i] == '"' {
+			start := i
+			i++
+			var b strings.Builder
+			closed := false
+			for i < len(s) {
+				switch {
+				// Only `\\` and `\"` are escapes; any other backslash is kept
+				// literally so regex operands like `5\d\d` round-trip intact
+				// instead of losing their escapes.
+				case s[i] == '\\' && i+1 < len(s) && (s[i+1] == '\\' || s[i+1] == '"'):
+					b.WriteByte(s[i+1])
+					i += 2
+				case s[i] == '"':
+					closed = true
+					i++
+				default:
+					b.WriteByte(s[i])
+					i++
+				}
+				if closed {
+					break
+				}
+			}
+			if !closed {
+				return nil, fmt.Errorf("logql: unterminated quoted string at %d", start)
+			}
+			toks = append(toks, b.String())
+			continue
+		}
+
+		start := i
+		for i < len(s) && s[i] != ' ' {
+			i++
+		}
+		toks = append(toks, s[start:i])
+	}
+
+	return toks, nil
+}
+
+// Eval runs line through every op in order, short-circuiting as soon as one
+// rejects it. It returns whether line survives the whole query, along with
+// the [start,end) byte ranges matched by the positive (`|=`/`|~`) ops, so
+// callers can highlight every matched region.
+func (q *Query) Eval(line []byte) (bool, [][2]int) {
+	ranges := make([][2]int, 0, len(q.Ops))
+	for _, op := range q.Ops {
+		switch op.Kind {
+		case OpContains, OpMatch:
+			loc := op.rx.FindIndex(line)
+			if loc == nil {
+				return false, nil
+			}
+			ranges = append(ranges, [2]int{loc[0], loc[1]})
+		case OpNotContains, OpNotMatch:
+			if op.rx.Match(line) {
+				return false, nil
+			}
+		}
+	}
+
+	return true, ranges
+}
@@ -0,0 +1,76 @@
+package logql
+
+import "testing"
+
+func TestParseAndEvalPrecedence(t *testing.T) {
+	q, err := Parse(`|~ "GET /api" != "health" |~ "5\d\d"`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(q.Ops) != 3 {
+		t.Fatalf("expected 3 ops, got %d", len(q.Ops))
+	}
+
+	tests := []struct {
+		line  string
+		match bool
+	}{
+		{"GET /api/v1/widgets -> 503", true},
+		{"GET /api/v1/health -> 200", false},
+		{"GET /other/v1/widgets -> 503", false},
+		{"GET /api/v1/widgets -> 200", false},
+	}
+	for _, tt := range tests {
+		ok, _ := q.Eval([]byte(tt.line))
+		if ok != tt.match {
+			t.Errorf("Eval(%q) = %v, want %v", tt.line, ok, tt.match)
+		}
+	}
+}
+
+func TestEvalCaseInsensitive(t *testing.T) {
+	q, err := Parse(`|= "error"`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	ok, ranges := q.Eval([]byte("this is an ERROR message"))
+	if !ok {
+		t.Fatalf("expected case-insensitive match")
+	}
+	if len(ranges) != 1 {
+		t.Fatalf("expected one matched range, got %d", len(ranges))
+	}
+
+	rxQ, err := Parse(`|~ "err.*"`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if ok, _ := rxQ.Eval([]byte("...ERROR message")); !ok {
+		t.Fatalf("expected case-insensitive regex match")
+	}
+}
+
+func TestParseQuotedEscapes(t *testing.T) {
+	q, err := Parse(`|= "a\"b\\c"`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got, want := q.Ops[0].Value, `a"b\c`; got != want {
+		t.Fatalf("Value = %q, want %q", got, want)
+	}
+}
+
+func TestParseInvalidGrammar(t *testing.T) {
+	tests := []string{
+		`|=`,
+		`"bare value"`,
+		`|= "a" |=`,
+		`|~ "["`,
+		`|= |~`,
+	}
+	for _, raw := range tests {
+		if _, err := Parse(raw); err == nil {
+			t.Errorf("Parse(%q) expected error, got nil", raw)
+		}
+	}
+}